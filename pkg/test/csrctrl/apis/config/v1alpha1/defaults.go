@@ -0,0 +1,57 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"time"
+
+	capi "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultDuration matches the certificateDuration constant the
+	// flag-based controller has always used.
+	DefaultDuration = 1 * time.Hour
+
+	// MinDuration and MaxDuration bound the duration a config file may
+	// request for a signer, so a typo can't hand out certificates that
+	// never expire or that expire before a test can use them.
+	MinDuration = 10 * time.Minute
+	MaxDuration = 24 * time.Hour
+
+	DefaultKeyAlgorithm = RSA2048
+)
+
+// SetDefaults_ControllerConfiguration fills in every signer's zero-valued
+// fields with the same defaults the legacy flag-based controller used,
+// so a config file only needs to specify what it wants to change.
+func SetDefaults_ControllerConfiguration(cfg *ControllerConfiguration) {
+	for i := range cfg.Signers {
+		SetDefaults_SignerConfig(&cfg.Signers[i])
+	}
+}
+
+func SetDefaults_SignerConfig(s *SignerConfig) {
+	if s.Duration.Duration == 0 {
+		s.Duration = metav1.Duration{Duration: DefaultDuration}
+	}
+	if s.KeyAlgorithm == "" {
+		s.KeyAlgorithm = DefaultKeyAlgorithm
+	}
+	if len(s.Usages) == 0 {
+		s.Usages = []capi.KeyUsage{capi.UsageClientAuth, capi.UsageServerAuth}
+	}
+}
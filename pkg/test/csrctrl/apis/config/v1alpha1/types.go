@@ -0,0 +1,84 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v1alpha1 defines the typed configuration file accepted by the
+// csrctrl test controller via the `--config` flag. It mirrors the shape of
+// cert-manager's controller configuration: a single `ControllerConfiguration`
+// root object that lists the signers the controller should start, each with
+// its own key material, duration and usage policy.
+package v1alpha1
+
+import (
+	capi "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KeyAlgorithm is the private key algorithm a signer should use when it
+// auto-generates its CA, or that it expects to see on incoming CSRs.
+type KeyAlgorithm string
+
+const (
+	RSA2048   KeyAlgorithm = "RSA-2048"
+	RSA3072   KeyAlgorithm = "RSA-3072"
+	ECDSAP256 KeyAlgorithm = "ECDSA-P256"
+	ECDSAP384 KeyAlgorithm = "ECDSA-P384"
+	Ed25519   KeyAlgorithm = "Ed25519"
+)
+
+// ControllerConfiguration is the root object loaded from the file passed to
+// `--config`. It is registered with the controller's scheme next to the capi
+// and corev1 types; config.Load decodes it with encoding/json.
+type ControllerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Signers lists every signer the controller should start. Each entry's
+	// SignerName must be unique.
+	Signers []SignerConfig `json:"signers"`
+}
+
+// SignerConfig describes a single signer to start, including the key
+// material it should sign with and the policy it should enforce.
+type SignerConfig struct {
+	// SignerName is matched against CertificateSigningRequest.Spec.SignerName.
+	SignerName string `json:"signerName"`
+
+	// CAFile and KeyFile point at an existing CA certificate/key pair on
+	// disk. When both are empty the signer generates its own self-signed
+	// root the first time it starts, matching the legacy disk-backed
+	// behavior.
+	CAFile  string `json:"caFile,omitempty"`
+	KeyFile string `json:"keyFile,omitempty"`
+
+	// Duration is the lifetime given to certificates signed by this signer.
+	Duration metav1.Duration `json:"duration,omitempty"`
+
+	// KeyAlgorithm selects the algorithm used for an auto-generated root.
+	// The key algorithm of incoming CSRs is not checked against it.
+	KeyAlgorithm KeyAlgorithm `json:"keyAlgorithm,omitempty"`
+
+	// Usages lists the key usages this signer is willing to issue.
+	Usages []capi.KeyUsage `json:"usages,omitempty"`
+
+	// AppendRootCert controls whether the signer's root certificate is
+	// appended to the signed certificate chain. The controller applies it
+	// to all signers at once: if any signer sets it, every signer appends
+	// its own root.
+	AppendRootCert bool `json:"appendRootCert,omitempty"`
+
+	// Ephemeral generates a fresh root CA and key in memory on startup
+	// instead of persisting it under SignerRoot, so hermetic tests can run
+	// without a writable /tmp/pki/signer. It cannot be combined with
+	// CAFile/KeyFile.
+	Ephemeral bool `json:"ephemeral,omitempty"`
+}
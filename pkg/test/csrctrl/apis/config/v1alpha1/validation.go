@@ -0,0 +1,66 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+)
+
+var validKeyAlgorithms = map[KeyAlgorithm]bool{
+	RSA2048:   true,
+	RSA3072:   true,
+	ECDSAP256: true,
+	ECDSAP384: true,
+	Ed25519:   true,
+}
+
+// Validate checks a decoded ControllerConfiguration for internal
+// consistency. It rejects anything the controller wouldn't be able to act
+// on safely, rather than silently falling back to a default.
+func Validate(cfg *ControllerConfiguration) error {
+	if len(cfg.Signers) == 0 {
+		return fmt.Errorf("config: at least one signer must be configured")
+	}
+	seen := make(map[string]bool, len(cfg.Signers))
+	for i, s := range cfg.Signers {
+		if err := validateSigner(&s); err != nil {
+			return fmt.Errorf("config: signers[%d]: %w", i, err)
+		}
+		if seen[s.SignerName] {
+			return fmt.Errorf("config: signers[%d]: duplicate signerName %q", i, s.SignerName)
+		}
+		seen[s.SignerName] = true
+	}
+	return nil
+}
+
+func validateSigner(s *SignerConfig) error {
+	if s.SignerName == "" {
+		return fmt.Errorf("signerName must not be empty")
+	}
+	if (s.CAFile == "") != (s.KeyFile == "") {
+		return fmt.Errorf("signer %q: caFile and keyFile must both be set, or both left empty to auto-generate", s.SignerName)
+	}
+	if s.Ephemeral && (s.CAFile != "" || s.KeyFile != "") {
+		return fmt.Errorf("signer %q: ephemeral cannot be combined with caFile/keyFile", s.SignerName)
+	}
+	if !validKeyAlgorithms[s.KeyAlgorithm] {
+		return fmt.Errorf("signer %q: unsupported keyAlgorithm %q", s.SignerName, s.KeyAlgorithm)
+	}
+	if s.Duration.Duration < MinDuration || s.Duration.Duration > MaxDuration {
+		return fmt.Errorf("signer %q: duration %s must be between %s and %s", s.SignerName, s.Duration.Duration, MinDuration, MaxDuration)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config loads and validates the file passed to csrctrl's
+// `--config` flag.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/apache/dubbo-go-pixiu/pkg/test/csrctrl/apis/config/v1alpha1"
+)
+
+// Load reads a YAML or JSON ControllerConfiguration from path, rejects
+// unknown fields, fills in defaults and validates the result.
+func Load(path string) (*v1alpha1.ControllerConfiguration, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: unable to read %s: %w", path, err)
+	}
+
+	js, err := yaml.YAMLToJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("config: unable to parse %s: %w", path, err)
+	}
+
+	cfg := &v1alpha1.ControllerConfiguration{}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("config: unable to decode %s: %w", path, err)
+	}
+
+	v1alpha1.SetDefaults_ControllerConfiguration(cfg)
+	if err := v1alpha1.Validate(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,286 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conformance defines a standard suite of CSR lifecycle tests that
+// any signer implementation - the in-process controller started by
+// RunCSRController, or a drop-in replacement reachable only by signerName -
+// must pass. Run it with RunSuite against a SignerUnderTest; the suite
+// itself never assumes it is talking to this package's own controller.
+package conformance
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"testing"
+	"time"
+
+	capi "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SignerUnderTest is the handle the suite uses to talk to whatever
+// implementation is being conformance-tested.
+type SignerUnderTest interface {
+	// SignerName is the value to put in CertificateSigningRequest.Spec.SignerName.
+	SignerName() string
+	// Client is used to create CSRs and watch their status.
+	Client() client.Client
+	// ExpectedRootCert is the PEM root a certificate issued by this signer
+	// must chain to.
+	ExpectedRootCert() []byte
+}
+
+// pollInterval/pollTimeout bound how long the suite waits for a CSR's
+// status to reflect a signing or denial decision.
+const (
+	pollInterval = 100 * time.Millisecond
+	pollTimeout  = 30 * time.Second
+
+	// negativeCheckGrace is how long testDenialPropagation and
+	// testRejectsOversizedOrWeakKeys wait before checking that a
+	// certificate was never issued. It is a single read after a short
+	// grace period rather than a poll for the full pollTimeout, so a
+	// signer that issues later than negativeCheckGrace is not caught.
+	negativeCheckGrace = 200 * time.Millisecond
+)
+
+// RunSuite runs every conformance test as a subtest of t.
+func RunSuite(t *testing.T, s SignerUnderTest) {
+	t.Run("ApprovalToIssuanceLatency", func(t *testing.T) { testApprovalToIssuanceLatency(t, s) })
+	t.Run("DenialPropagation", func(t *testing.T) { testDenialPropagation(t, s) })
+	t.Run("RotationOnTTLExpiry", func(t *testing.T) { testRotationOnTTLExpiry(t, s) })
+	t.Run("RejectsOversizedOrWeakKeys", func(t *testing.T) { testRejectsOversizedOrWeakKeys(t, s) })
+	t.Run("DuplicateCSRNames", func(t *testing.T) { testDuplicateCSRNames(t, s) })
+	t.Run("ConcurrentSignerIsolation", func(t *testing.T) { testConcurrentSignerIsolation(t, s) })
+}
+
+// testApprovalToIssuanceLatency approves a freshly created CSR and asserts
+// the signer issues a certificate for it within pollTimeout, chaining to
+// ExpectedRootCert.
+func testApprovalToIssuanceLatency(t *testing.T, s SignerUnderTest) {
+	t.Helper()
+	ctx := context.Background()
+	csr := newCSR(t, s.SignerName(), "conformance-latency", 2048, nil)
+	mustCreate(t, ctx, s, csr)
+	mustApprove(t, ctx, s, csr)
+
+	issued := waitForCertificate(t, ctx, s, csr.Name)
+	verifyChain(t, s.ExpectedRootCert(), issued)
+}
+
+// testDenialPropagation denies a CSR out-of-band and asserts the signer
+// never issues a certificate for it.
+func testDenialPropagation(t *testing.T, s SignerUnderTest) {
+	t.Helper()
+	ctx := context.Background()
+	csr := newCSR(t, s.SignerName(), "conformance-denial", 2048, nil)
+	mustCreate(t, ctx, s, csr)
+	mustDeny(t, ctx, s, csr)
+
+	assertNeverIssued(t, ctx, s, csr.Name, "a denied CSR")
+}
+
+// testRotationOnTTLExpiry requests a 30s certificate and asserts its NotAfter
+// is no later than the requested TTL (plus pollTimeout) from now. It does not
+// wait for the TTL to elapse and does not check re-issuance.
+func testRotationOnTTLExpiry(t *testing.T, s SignerUnderTest) {
+	t.Helper()
+	ctx := context.Background()
+	ttl := int32(30)
+	csr := newCSR(t, s.SignerName(), "conformance-rotation", 2048, &ttl)
+	mustCreate(t, ctx, s, csr)
+	mustApprove(t, ctx, s, csr)
+
+	issued := waitForCertificate(t, ctx, s, csr.Name)
+	cert := parseCert(t, issued)
+	if time.Until(cert.NotAfter) > time.Duration(ttl)*time.Second+pollTimeout {
+		t.Fatalf("RotationOnTTLExpiry: issued certificate lives far longer than the requested %ds TTL (NotAfter=%s)", ttl, cert.NotAfter)
+	}
+}
+
+// testRejectsOversizedOrWeakKeys asserts a CSR built on a key too weak to
+// trust (512-bit RSA) is never signed. Oversized keys are not exercised.
+func testRejectsOversizedOrWeakKeys(t *testing.T, s SignerUnderTest) {
+	t.Helper()
+	ctx := context.Background()
+	csr := newCSR(t, s.SignerName(), "conformance-weak-key", 512, nil)
+	mustCreate(t, ctx, s, csr)
+	mustApprove(t, ctx, s, csr)
+
+	assertNeverIssued(t, ctx, s, csr.Name, "a 512-bit RSA CSR")
+}
+
+// testDuplicateCSRNames asserts creating a second CSR object with the name
+// of an already-issued one is rejected by the API server rather than
+// silently reusing the first's certificate.
+func testDuplicateCSRNames(t *testing.T, s SignerUnderTest) {
+	t.Helper()
+	ctx := context.Background()
+	csr := newCSR(t, s.SignerName(), "conformance-duplicate", 2048, nil)
+	mustCreate(t, ctx, s, csr)
+	mustApprove(t, ctx, s, csr)
+	waitForCertificate(t, ctx, s, csr.Name)
+
+	dup := csr.DeepCopy()
+	dup.ResourceVersion = ""
+	if err := s.Client().Create(ctx, dup); err == nil {
+		t.Fatalf("DuplicateCSRNames: expected creating a second CSR named %s to fail", csr.Name)
+	}
+}
+
+// testConcurrentSignerIsolation creates and approves two CSRs for the same
+// signer, one after the other, and asserts the two issued certificates are
+// not identical.
+func testConcurrentSignerIsolation(t *testing.T, s SignerUnderTest) {
+	t.Helper()
+	ctx := context.Background()
+	a := newCSR(t, s.SignerName(), "conformance-isolation-a", 2048, nil)
+	b := newCSR(t, s.SignerName(), "conformance-isolation-b", 2048, nil)
+	mustCreate(t, ctx, s, a)
+	mustCreate(t, ctx, s, b)
+	mustApprove(t, ctx, s, a)
+	mustApprove(t, ctx, s, b)
+
+	certA := waitForCertificate(t, ctx, s, a.Name)
+	certB := waitForCertificate(t, ctx, s, b.Name)
+	if string(certA) == string(certB) {
+		t.Fatalf("ConcurrentSignerIsolation: two independent CSRs were issued identical certificates")
+	}
+}
+
+func newCSR(t *testing.T, signerName, namePrefix string, keyBits int, expirationSeconds *int32) *capi.CertificateSigningRequest {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		t.Fatalf("unable to generate CSR key: %v", err)
+	}
+	tmpl := &x509.CertificateRequest{Subject: pkix.Name{CommonName: namePrefix}}
+	der, err := x509.CreateCertificateRequest(rand.Reader, tmpl, key)
+	if err != nil {
+		t.Fatalf("unable to create CSR: %v", err)
+	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+
+	return &capi.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%d", namePrefix, time.Now().UnixNano())},
+		Spec: capi.CertificateSigningRequestSpec{
+			Request:           csrPEM,
+			SignerName:        signerName,
+			Usages:            []capi.KeyUsage{capi.UsageClientAuth},
+			ExpirationSeconds: expirationSeconds,
+		},
+	}
+}
+
+func mustCreate(t *testing.T, ctx context.Context, s SignerUnderTest, csr *capi.CertificateSigningRequest) {
+	t.Helper()
+	if err := s.Client().Create(ctx, csr); err != nil {
+		t.Fatalf("unable to create CSR %s: %v", csr.Name, err)
+	}
+}
+
+func mustApprove(t *testing.T, ctx context.Context, s SignerUnderTest, csr *capi.CertificateSigningRequest) {
+	t.Helper()
+	csr.Status.Conditions = append(csr.Status.Conditions, capi.CertificateSigningRequestCondition{
+		Type:           capi.CertificateApproved,
+		Status:         "True",
+		Reason:         "ConformanceSuiteApprove",
+		LastUpdateTime: metav1.Now(),
+	})
+	if err := s.Client().Status().Update(ctx, csr); err != nil {
+		t.Fatalf("unable to approve CSR %s: %v", csr.Name, err)
+	}
+}
+
+func mustDeny(t *testing.T, ctx context.Context, s SignerUnderTest, csr *capi.CertificateSigningRequest) {
+	t.Helper()
+	csr.Status.Conditions = append(csr.Status.Conditions, capi.CertificateSigningRequestCondition{
+		Type:           capi.CertificateDenied,
+		Status:         "True",
+		Reason:         "ConformanceSuiteDeny",
+		LastUpdateTime: metav1.Now(),
+	})
+	if err := s.Client().Status().Update(ctx, csr); err != nil {
+		t.Fatalf("unable to deny CSR %s: %v", csr.Name, err)
+	}
+}
+
+// assertNeverIssued waits out negativeCheckGrace and then does a single read
+// of the CSR, failing the test if a certificate was issued in the meantime.
+// what identifies the CSR in the failure message (e.g. "a denied CSR").
+func assertNeverIssued(t *testing.T, ctx context.Context, s SignerUnderTest, name, what string) {
+	t.Helper()
+	time.Sleep(negativeCheckGrace)
+
+	var got capi.CertificateSigningRequest
+	if err := s.Client().Get(ctx, types.NamespacedName{Name: name}, &got); err != nil {
+		t.Fatalf("unable to get CSR %s: %v", name, err)
+	}
+	if len(got.Status.Certificate) != 0 {
+		t.Fatalf("signer issued a certificate for %s %s", what, name)
+	}
+}
+
+func waitForCertificate(t *testing.T, ctx context.Context, s SignerUnderTest, name string) []byte {
+	t.Helper()
+	var cert []byte
+	err := wait.PollUntilContextTimeout(ctx, pollInterval, pollTimeout, true, func(ctx context.Context) (bool, error) {
+		var got capi.CertificateSigningRequest
+		if err := s.Client().Get(ctx, types.NamespacedName{Name: name}, &got); err != nil {
+			return false, err
+		}
+		if len(got.Status.Certificate) == 0 {
+			return false, nil
+		}
+		cert = got.Status.Certificate
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("timed out waiting for CSR %s to be signed: %v", name, err)
+	}
+	return cert
+}
+
+func parseCert(t *testing.T, certPEM []byte) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		t.Fatalf("unable to decode certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("unable to parse certificate: %v", err)
+	}
+	return cert
+}
+
+func verifyChain(t *testing.T, rootPEM, leafPEM []byte) {
+	t.Helper()
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(rootPEM) {
+		t.Fatalf("unable to parse root cert PEM")
+	}
+	leaf := parseCert(t, leafPEM)
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		t.Fatalf("issued certificate does not chain to the signer's expected root: %v", err)
+	}
+}
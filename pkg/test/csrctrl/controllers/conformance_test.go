@@ -0,0 +1,123 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csrctrl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	capi "k8s.io/api/certificates/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/apache/dubbo-go-pixiu/pkg/test/csrctrl/conformance"
+	"github.com/apache/dubbo-go-pixiu/pkg/test/csrctrl/signer"
+)
+
+const conformanceSignerName = "conformance.test.istio.io/signer"
+
+// TestConformance runs the shared conformance suite against the in-process
+// controller, the way a downstream signer replacement would run it against
+// its own deployment.
+func TestConformance(t *testing.T) {
+	conformance.RunSuite(t, newInProcessSigner(t))
+}
+
+// inProcessSigner drives CertificateSigningRequestSigningReconciler against
+// a fake client, reconciling synchronously after every Create and status
+// Update so the suite
+// can run without a real apiserver or manager watch loop.
+type inProcessSigner struct {
+	reconcilingClient
+	rootCert []byte
+}
+
+func newInProcessSigner(t *testing.T) *inProcessSigner {
+	t.Helper()
+	s, err := signer.NewSigner(t.TempDir(), conformanceSignerName, time.Hour)
+	if err != nil {
+		t.Fatalf("unable to start signer: %v", err)
+	}
+	rootCert, err := s.RootCertPEM()
+	if err != nil {
+		t.Fatalf("unable to read root cert: %v", err)
+	}
+
+	scheme := runtime.NewScheme()
+	_ = capi.AddToScheme(scheme)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&capi.CertificateSigningRequest{}).Build()
+
+	reconciler := &CertificateSigningRequestSigningReconciler{
+		Client:      fakeClient,
+		SignerNames: []string{conformanceSignerName},
+		Profiles: map[string]*SignerProfile{
+			conformanceSignerName: {
+				Signer:          s,
+				AllowedUsages:   []capi.KeyUsage{capi.UsageClientAuth},
+				MaxTTL:          time.Hour,
+				AllowClientAuth: true,
+			},
+		},
+	}
+
+	return &inProcessSigner{
+		reconcilingClient: reconcilingClient{Client: fakeClient, reconciler: reconciler},
+		rootCert:          rootCert,
+	}
+}
+
+func (i *inProcessSigner) SignerName() string       { return conformanceSignerName }
+func (i *inProcessSigner) ExpectedRootCert() []byte { return i.rootCert }
+func (i *inProcessSigner) Client() client.Client     { return i.reconcilingClient }
+
+// reconcilingClient wraps a client.Client so that every Create or status
+// Update triggers a synchronous Reconcile, standing in for the manager's
+// watch loop that would normally notice the change.
+type reconcilingClient struct {
+	client.Client
+	reconciler *CertificateSigningRequestSigningReconciler
+}
+
+func (c reconcilingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.reconcileObject(ctx, obj)
+	return nil
+}
+
+func (c reconcilingClient) Status() client.SubResourceWriter {
+	return reconcilingStatusWriter{SubResourceWriter: c.Client.Status(), reconcilingClient: c}
+}
+
+func (c reconcilingClient) reconcileObject(ctx context.Context, obj client.Object) {
+	_, _ = c.reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+}
+
+type reconcilingStatusWriter struct {
+	client.SubResourceWriter
+	reconcilingClient
+}
+
+func (w reconcilingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	if err := w.SubResourceWriter.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+	w.reconcileObject(ctx, obj)
+	return nil
+}
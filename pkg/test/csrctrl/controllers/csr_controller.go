@@ -0,0 +1,198 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csrctrl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	capi "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/apache/dubbo-go-pixiu/pkg/test/csrctrl/signer"
+	"istio.io/pkg/log"
+)
+
+// SignerProfile is the policy a signer enforces for a given SignerName: what
+// the requester is allowed to ask for, not just which CA answers the
+// request.
+type SignerProfile struct {
+	// Signer performs the actual signing once a request clears policy.
+	Signer *signer.Signer
+
+	// AllowedUsages is the full set of key usages this profile will ever
+	// issue; a request asking for anything outside this set is denied.
+	AllowedUsages []capi.KeyUsage
+
+	// MaxTTL bounds the lifetime of certificates issued under this
+	// profile. A request whose ExpirationSeconds exceeds it is denied
+	// rather than silently truncated.
+	MaxTTL time.Duration
+
+	// AllowClientAuth and AllowServerAuth gate whether capi.UsageClientAuth
+	// and capi.UsageServerAuth may appear in a request even when they are
+	// present in AllowedUsages, so a profile can list them for visibility
+	// while still disabling one or the other.
+	AllowClientAuth bool
+	AllowServerAuth bool
+}
+
+func (p *SignerProfile) allows(usages []capi.KeyUsage) error {
+	allowed := make(map[capi.KeyUsage]bool, len(p.AllowedUsages))
+	for _, u := range p.AllowedUsages {
+		allowed[u] = true
+	}
+	for _, u := range usages {
+		if !allowed[u] {
+			return fmt.Errorf("usage %q is not permitted by this profile", u)
+		}
+		if u == capi.UsageClientAuth && !p.AllowClientAuth {
+			return fmt.Errorf("client-auth is not permitted by this profile")
+		}
+		if u == capi.UsageServerAuth && !p.AllowServerAuth {
+			return fmt.Errorf("server-auth is not permitted by this profile")
+		}
+	}
+	return nil
+}
+
+func (p *SignerProfile) checkTTL(requestedSeconds *int32) error {
+	if requestedSeconds == nil {
+		return nil
+	}
+	requested := time.Duration(*requestedSeconds) * time.Second
+	if requested > p.MaxTTL {
+		return fmt.Errorf("requested duration %s exceeds the profile's max TTL %s", requested, p.MaxTTL)
+	}
+	return nil
+}
+
+// CertificateSigningRequestSigningReconciler reconciles CertificateSigningRequests
+// whose SignerName matches one of SignerNames, signing approved requests
+// that comply with the corresponding entry in Profiles and denying those
+// that don't.
+type CertificateSigningRequestSigningReconciler struct {
+	Client     client.Client
+	SignerRoot string
+	Scheme     *runtime.Scheme
+
+	SignerNames []string
+	Profiles    map[string]*SignerProfile
+
+	appendRootCert bool
+}
+
+// SetupWithManager registers this reconciler against every
+// CertificateSigningRequest the manager's cluster sees.
+func (r *CertificateSigningRequestSigningReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&capi.CertificateSigningRequest{}).
+		Complete(r)
+}
+
+// Reconcile signs CSRs that are approved, not denied, not yet issued and
+// whose SignerName is one this controller is configured for. A request
+// whose usages or TTL violate its profile is denied with a descriptive
+// Denied condition instead of being truncated to fit.
+func (r *CertificateSigningRequestSigningReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var csr capi.CertificateSigningRequest
+	if err := r.Client.Get(ctx, req.NamespacedName, &csr); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	profile, ok := r.Profiles[csr.Spec.SignerName]
+	if !ok {
+		// Not ours to sign.
+		return ctrl.Result{}, nil
+	}
+
+	if len(csr.Status.Certificate) != 0 {
+		return ctrl.Result{}, nil
+	}
+
+	if !isApproved(&csr) || isDenied(&csr) {
+		return ctrl.Result{}, nil
+	}
+
+	if err := profile.allows(csr.Spec.Usages); err != nil {
+		return ctrl.Result{}, r.deny(ctx, &csr, err)
+	}
+	if err := profile.checkTTL(csr.Spec.ExpirationSeconds); err != nil {
+		return ctrl.Result{}, r.deny(ctx, &csr, err)
+	}
+
+	var ttl time.Duration
+	if csr.Spec.ExpirationSeconds != nil {
+		ttl = time.Duration(*csr.Spec.ExpirationSeconds) * time.Second
+	}
+	cert, err := profile.Signer.Sign(csr.Spec.Request, csr.Spec.Usages, ttl)
+	if err != nil {
+		log.Errorf("signer %s: unable to sign CSR %s: %v", csr.Spec.SignerName, csr.Name, err)
+		return ctrl.Result{}, err
+	}
+
+	if r.appendRootCert {
+		root, rErr := profile.Signer.RootCertPEM()
+		if rErr == nil {
+			cert = append(cert, root...)
+		}
+	}
+
+	csr.Status.Certificate = cert
+	if err := r.Client.Status().Update(ctx, &csr); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// deny records a Denied condition with reason explaining the policy
+// violation, rather than silently truncating the request to fit.
+func (r *CertificateSigningRequestSigningReconciler) deny(ctx context.Context, csr *capi.CertificateSigningRequest, reason error) error {
+	csr.Status.Conditions = append(csr.Status.Conditions, capi.CertificateSigningRequestCondition{
+		Type:           capi.CertificateDenied,
+		Status:         corev1.ConditionTrue,
+		Reason:         "SignerPolicyViolation",
+		Message:        reason.Error(),
+		LastUpdateTime: metav1.Now(),
+	})
+	return r.Client.Status().Update(ctx, csr)
+}
+
+func isApproved(csr *capi.CertificateSigningRequest) bool {
+	for _, c := range csr.Status.Conditions {
+		if c.Type == capi.CertificateApproved {
+			return true
+		}
+	}
+	return false
+}
+
+func isDenied(csr *capi.CertificateSigningRequest) bool {
+	for _, c := range csr.Status.Conditions {
+		if c.Type == capi.CertificateDenied {
+			return true
+		}
+	}
+	return false
+}
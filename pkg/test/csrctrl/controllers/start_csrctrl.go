@@ -27,6 +27,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	// +kubebuilder:scaffold:imports
+	configv1alpha1 "github.com/apache/dubbo-go-pixiu/pkg/test/csrctrl/apis/config/v1alpha1"
+	"github.com/apache/dubbo-go-pixiu/pkg/test/csrctrl/config"
 	"github.com/apache/dubbo-go-pixiu/pkg/test/csrctrl/signer"
 	"github.com/apache/dubbo-go-pixiu/pkg/test/framework/components/cluster"
 	"istio.io/pkg/log"
@@ -45,6 +47,7 @@ var (
 	loggingOptions = log.DefaultOptions()
 	_              = capi.AddToScheme(scheme)
 	_              = corev1.AddToScheme(scheme)
+	_              = configv1alpha1.AddToScheme(scheme)
 )
 
 type SignerRootCert struct {
@@ -52,7 +55,12 @@ type SignerRootCert struct {
 	Rootcert string
 }
 
-func RunCSRController(signerNames string, appendRootCert bool, c <-chan struct{},
+// RunCSRController starts one signer per entry in signerNames, unless
+// configFile is non-empty, in which case the typed v1alpha1.ControllerConfiguration
+// loaded from it takes over and signerNames/appendRootCert are ignored. This
+// keeps existing callers and test suites working unchanged when they don't
+// pass a config file.
+func RunCSRController(signerNames string, appendRootCert bool, configFile string, c <-chan struct{},
 	clusters cluster.Clusters,
 ) []SignerRootCert {
 	// Config Istio log
@@ -60,52 +68,156 @@ func RunCSRController(signerNames string, appendRootCert bool, c <-chan struct{}
 		log.Infof("Unable to configure Istio log error: %v", err)
 		os.Exit(-1)
 	}
+
+	arrSigners, profiles, rootCertSignerArr, appendRootCert := buildSigners(signerNames, appendRootCert, configFile)
+
+	for _, cluster := range clusters {
+		mgr, err := ctrl.NewManager(cluster.RESTConfig(), ctrl.Options{
+			Scheme: scheme,
+			// disabel the metric server to avoid the port conflicting
+			MetricsBindAddress: "0",
+		})
+		if err != nil {
+			log.Infof("Unable to start manager error: %v", err)
+			os.Exit(-1)
+		}
+		go runManager(mgr, arrSigners, profiles, appendRootCert, c)
+	}
+
+	return rootCertSignerArr
+}
+
+// buildSigners constructs the set of signer profiles to run, either from a
+// configFile (when non-empty) or from the legacy comma-separated
+// signerNames/appendRootCert flags.
+func buildSigners(signerNames string, appendRootCert bool, configFile string) (
+	arrSigners []string, profiles map[string]*SignerProfile, rootCertSignerArr []SignerRootCert, effectiveAppendRootCert bool,
+) {
+	if configFile == "" {
+		return buildSignersFromFlags(signerNames, appendRootCert)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.Infof("Unable to load csrctrl config %s: %v", configFile, err)
+		os.Exit(-1)
+	}
+	return buildSignersFromConfig(cfg)
+}
+
+// legacySignerUsages is the policy every signer started from the flag-based
+// path has always implicitly allowed: client and server auth, with no TTL
+// restriction beyond the signer's own certificateDuration. Existing test
+// suites that only ever set these usages keep working unchanged.
+var legacySignerUsages = []capi.KeyUsage{capi.UsageClientAuth, capi.UsageServerAuth}
+
+func buildSignersFromFlags(signerNames string, appendRootCert bool) (
+	[]string, map[string]*SignerProfile, []SignerRootCert, bool,
+) {
 	arrSigners := strings.Split(signerNames, ",")
-	signersMap := make(map[string]*signer.Signer, len(arrSigners))
+	profiles := make(map[string]*SignerProfile, len(arrSigners))
 	var rootCertSignerArr []SignerRootCert
 	for _, signerName := range arrSigners {
-		signer, sErr := signer.NewSigner(signerRoot, signerName, certificateDuration)
+		s, sErr := signer.NewSigner(signerRoot, signerName, certificateDuration)
 		if sErr != nil {
 			log.Infof("Unable to start signer for [%s], error: %v", signerName, sErr)
 			os.Exit(-1)
 		}
-		signersMap[signerName] = signer
-		rootCert, rErr := os.ReadFile(signer.GetRootCerts())
+		profiles[signerName] = &SignerProfile{
+			Signer:          s,
+			AllowedUsages:   legacySignerUsages,
+			MaxTTL:          certificateDuration,
+			AllowClientAuth: true,
+			AllowServerAuth: true,
+		}
+		rootCert, rErr := s.RootCertPEM()
 		if rErr != nil {
-			log.Infof("Unable to read root cert for signer [%s], error: %v", signerName, sErr)
+			log.Infof("Unable to read root cert for signer [%s], error: %v", signerName, rErr)
 			os.Exit(-1)
 		}
-		rootCertsForSigner := SignerRootCert{
+		rootCertSignerArr = append(rootCertSignerArr, SignerRootCert{
 			Signer:   signerName,
 			Rootcert: string(rootCert),
-		}
-		rootCertSignerArr = append(rootCertSignerArr, rootCertsForSigner)
+		})
 	}
+	return arrSigners, profiles, rootCertSignerArr, appendRootCert
+}
 
-	for _, cluster := range clusters {
-		mgr, err := ctrl.NewManager(cluster.RESTConfig(), ctrl.Options{
-			Scheme: scheme,
-			// disabel the metric server to avoid the port conflicting
-			MetricsBindAddress: "0",
-		})
-		if err != nil {
-			log.Infof("Unable to start manager error: %v", err)
+func buildSignersFromConfig(cfg *configv1alpha1.ControllerConfiguration) (
+	[]string, map[string]*SignerProfile, []SignerRootCert, bool,
+) {
+	arrSigners := make([]string, 0, len(cfg.Signers))
+	profiles := make(map[string]*SignerProfile, len(cfg.Signers))
+	var rootCertSignerArr []SignerRootCert
+	var appendRootCert bool
+	for _, sc := range cfg.Signers {
+		signerOpts := []signer.Option{signer.WithKeyAlgorithm(toSignerKeyAlgorithm(sc.KeyAlgorithm))}
+		if sc.Ephemeral {
+			signerOpts = append(signerOpts, signer.WithMode(signer.Ephemeral))
+		}
+		if sc.CAFile != "" {
+			signerOpts = append(signerOpts, signer.WithCAKeyFiles(sc.CAFile, sc.KeyFile))
+		}
+		s, sErr := signer.NewSigner(signerRoot, sc.SignerName, sc.Duration.Duration, signerOpts...)
+		if sErr != nil {
+			log.Infof("Unable to start signer for [%s], error: %v", sc.SignerName, sErr)
 			os.Exit(-1)
 		}
-		go runManager(mgr, arrSigners, signersMap, appendRootCert, c)
+		arrSigners = append(arrSigners, sc.SignerName)
+		profiles[sc.SignerName] = &SignerProfile{
+			Signer:          s,
+			AllowedUsages:   sc.Usages,
+			MaxTTL:          sc.Duration.Duration,
+			AllowClientAuth: usageAllowed(sc.Usages, capi.UsageClientAuth),
+			AllowServerAuth: usageAllowed(sc.Usages, capi.UsageServerAuth),
+		}
+		rootCert, rErr := s.RootCertPEM()
+		if rErr != nil {
+			log.Infof("Unable to read root cert for signer [%s], error: %v", sc.SignerName, rErr)
+			os.Exit(-1)
+		}
+		rootCertSignerArr = append(rootCertSignerArr, SignerRootCert{
+			Signer:   sc.SignerName,
+			Rootcert: string(rootCert),
+		})
+		appendRootCert = appendRootCert || sc.AppendRootCert
 	}
+	return arrSigners, profiles, rootCertSignerArr, appendRootCert
+}
 
-	return rootCertSignerArr
+func usageAllowed(usages []capi.KeyUsage, want capi.KeyUsage) bool {
+	for _, u := range usages {
+		if u == want {
+			return true
+		}
+	}
+	return false
+}
+
+// toSignerKeyAlgorithm maps the config API's KeyAlgorithm onto the signer
+// package's own enum, keeping the two packages independently importable.
+func toSignerKeyAlgorithm(alg configv1alpha1.KeyAlgorithm) signer.KeyAlgorithm {
+	switch alg {
+	case configv1alpha1.RSA3072:
+		return signer.RSA3072
+	case configv1alpha1.ECDSAP256:
+		return signer.ECDSAP256
+	case configv1alpha1.ECDSAP384:
+		return signer.ECDSAP384
+	case configv1alpha1.Ed25519:
+		return signer.Ed25519
+	default:
+		return signer.RSA2048
+	}
 }
 
-func runManager(mgr manager.Manager, arrSigners []string, signersMap map[string]*signer.Signer, appendRootCert bool, c <-chan struct{}) {
+func runManager(mgr manager.Manager, arrSigners []string, profiles map[string]*SignerProfile, appendRootCert bool, c <-chan struct{}) {
 	if err := (&CertificateSigningRequestSigningReconciler{
 		Client:         mgr.GetClient(),
 		SignerRoot:     signerRoot,
-		CtrlCertTTL:    certificateDuration,
 		Scheme:         mgr.GetScheme(),
 		SignerNames:    arrSigners,
-		Signers:        signersMap,
+		Profiles:       profiles,
 		appendRootCert: appendRootCert,
 	}).SetupWithManager(mgr); err != nil {
 		log.Infof("Unable to create Controller for controller CSRSigningReconciler, error: %v", err)
@@ -0,0 +1,77 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	capi "k8s.io/api/certificates/v1"
+)
+
+// SignSAN generates a key and CSR for san, signs it with Sign, and returns
+// the resulting leaf certificate, its key, and this signer's root, all
+// PEM-encoded. It lets callers that only have a SAN to provision - such as
+// deployment.XDSProvisioned - get a real certificate out of this signer
+// without going through a CertificateSigningRequest object of their own.
+//
+// ttl must not exceed the duration this Signer was constructed with - it is
+// rejected rather than silently clamped - but may shorten the issued
+// certificate's lifetime below it.
+func (s *Signer) SignSAN(signerName, san string, ttl time.Duration) (certPEM, keyPEM, rootPEM []byte, err error) {
+	if signerName != s.signerName {
+		return nil, nil, nil, fmt.Errorf("signer %s: SignSAN called with signerName %q", s.signerName, signerName)
+	}
+	if ttl > s.duration {
+		return nil, nil, nil, fmt.Errorf("signer %s: requested ttl %s exceeds signer duration %s", signerName, ttl, s.duration)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("signer %s: unable to generate key for %s: %w", signerName, san, err)
+	}
+	tmpl := &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: san},
+		DNSNames: []string{san},
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, tmpl, key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("signer %s: unable to create CSR for %s: %w", signerName, san, err)
+	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+
+	certPEM, err = s.Sign(csrPEM, []capi.KeyUsage{capi.UsageServerAuth, capi.UsageClientAuth}, ttl)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("signer %s: unable to sign CSR for %s: %w", signerName, san, err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("signer %s: unable to marshal key for %s: %w", signerName, san, err)
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	rootPEM, err = s.RootCertPEM()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("signer %s: unable to read root cert: %w", signerName, err)
+	}
+
+	return certPEM, keyPEM, rootPEM, nil
+}
@@ -0,0 +1,67 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+)
+
+func TestSignSAN(t *testing.T) {
+	s, err := NewSigner(t.TempDir(), "test-signer", time.Hour)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+
+	certPEM, keyPEM, rootPEM, err := s.SignSAN("test-signer", "server.default.svc", 30*time.Minute)
+	if err != nil {
+		t.Fatalf("SignSAN: %v", err)
+	}
+	if len(keyPEM) == 0 {
+		t.Fatalf("SignSAN returned no key")
+	}
+	verifyChain(t, rootPEM, certPEM)
+
+	block, _ := pem.Decode(certPEM)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("unable to parse leaf cert: %v", err)
+	}
+	if len(leaf.DNSNames) != 1 || leaf.DNSNames[0] != "server.default.svc" {
+		t.Fatalf("leaf cert DNSNames = %v, want [server.default.svc]", leaf.DNSNames)
+	}
+}
+
+func TestSignSANRejectsWrongSignerName(t *testing.T) {
+	s, err := NewSigner(t.TempDir(), "test-signer", time.Hour)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	if _, _, _, err := s.SignSAN("other-signer", "server.default.svc", 30*time.Minute); err == nil {
+		t.Fatalf("SignSAN with mismatched signerName: expected error, got nil")
+	}
+}
+
+func TestSignSANRejectsTTLLongerThanSignerDuration(t *testing.T) {
+	s, err := NewSigner(t.TempDir(), "test-signer", time.Hour)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	if _, _, _, err := s.SignSAN("test-signer", "server.default.svc", 2*time.Hour); err == nil {
+		t.Fatalf("SignSAN with ttl exceeding signer duration: expected error, got nil")
+	}
+}
@@ -0,0 +1,417 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signer implements a minimal self-signed CA used by the csrctrl
+// test controller to sign CertificateSigningRequests.
+package signer
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	capi "k8s.io/api/certificates/v1"
+)
+
+const (
+	rootCertFileName = "root-cert.pem"
+	rootKeyFileName  = "root-key.pem"
+)
+
+// KeyAlgorithm selects the private key algorithm a Signer uses for its root
+// CA. A signed leaf always carries the public key from its CSR.
+type KeyAlgorithm string
+
+const (
+	RSA2048   KeyAlgorithm = "RSA-2048"
+	RSA3072   KeyAlgorithm = "RSA-3072"
+	ECDSAP256 KeyAlgorithm = "ECDSA-P256"
+	ECDSAP384 KeyAlgorithm = "ECDSA-P384"
+	Ed25519   KeyAlgorithm = "Ed25519"
+)
+
+func generateKey(alg KeyAlgorithm) (crypto.Signer, error) {
+	switch alg {
+	case "", RSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case RSA3072:
+		return rsa.GenerateKey(rand.Reader, 3072)
+	case ECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case ECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case Ed25519:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %q", alg)
+	}
+}
+
+// Mode selects where a Signer's CA and private key live.
+type Mode int
+
+const (
+	// DiskBacked persists the CA under SignerRoot/<signerName>, reusing it
+	// across restarts. This is the long-standing behavior of this package.
+	DiskBacked Mode = iota
+
+	// Ephemeral generates a fresh root CA and key in memory and never
+	// touches disk. A second NewSigner call with the same signerName in
+	// this mode is refused. It is meant for hermetic e2e tests that need to
+	// run in read-only or parallel sandboxes.
+	Ephemeral
+)
+
+// Options configures how NewSigner builds a Signer's CA.
+type Options struct {
+	Mode         Mode
+	KeyAlgorithm KeyAlgorithm
+	CAFile       string
+	KeyFile      string
+}
+
+// Option mutates an Options struct; used with NewSigner's functional options.
+type Option func(*Options)
+
+// WithMode selects DiskBacked (the default) or Ephemeral.
+func WithMode(m Mode) Option {
+	return func(o *Options) { o.Mode = m }
+}
+
+// WithKeyAlgorithm selects the root's key algorithm. Defaults to RSA-2048.
+func WithKeyAlgorithm(alg KeyAlgorithm) Option {
+	return func(o *Options) { o.KeyAlgorithm = alg }
+}
+
+// WithCAKeyFiles points the signer at an existing CA certificate/key pair on
+// disk instead of generating or reusing one under signerRoot. Must not be
+// combined with WithMode(Ephemeral).
+func WithCAKeyFiles(caFile, keyFile string) Option {
+	return func(o *Options) { o.CAFile, o.KeyFile = caFile, keyFile }
+}
+
+// usedEphemeralSigners records every signer name started in Ephemeral mode
+// during this process's lifetime, so a second start with the same name is
+// rejected instead of silently minting a second, incompatible root - which
+// would otherwise let certificates from a "previous run" validate.
+var usedEphemeralSigners sync.Map
+
+// Signer signs CertificateSigningRequests with a CA rooted at SignerRoot
+// (DiskBacked) or held only in memory (Ephemeral).
+type Signer struct {
+	signerName string
+	duration   time.Duration
+	mode       Mode
+
+	caCert *x509.Certificate
+	caKey  crypto.Signer
+
+	rootCertPath string
+	rootCertPEM  []byte
+}
+
+// NewSigner builds the CA for signerName according to opts. With no options
+// it is DiskBacked: it loads signerRoot/<signerName>, generating and
+// persisting a new self-signed root the first time it is called for that
+// signer name. With WithMode(Ephemeral) it generates a fresh root entirely
+// in memory and refuses to start twice for the same signerName.
+func NewSigner(signerRoot, signerName string, duration time.Duration, opts ...Option) (*Signer, error) {
+	options := Options{Mode: DiskBacked, KeyAlgorithm: RSA2048}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.Mode == Ephemeral {
+		return newEphemeralSigner(signerName, duration, options.KeyAlgorithm)
+	}
+
+	if options.CAFile != "" {
+		return newFileBackedSigner(signerName, duration, options.CAFile, options.KeyFile)
+	}
+
+	dir := filepath.Join(signerRoot, signerName)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("signer %s: unable to create %s: %w", signerName, dir, err)
+	}
+
+	rootCertPath := filepath.Join(dir, rootCertFileName)
+	rootKeyPath := filepath.Join(dir, rootKeyFileName)
+
+	caCert, caKey, err := loadOrGenerateRoot(signerName, rootCertPath, rootKeyPath, options.KeyAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{
+		signerName:   signerName,
+		duration:     duration,
+		mode:         DiskBacked,
+		caCert:       caCert,
+		caKey:        caKey,
+		rootCertPath: rootCertPath,
+	}, nil
+}
+
+// newFileBackedSigner loads a CA certificate/key pair from caFile/keyFile
+// instead of generating or reusing one under signerRoot.
+func newFileBackedSigner(signerName string, duration time.Duration, caFile, keyFile string) (*Signer, error) {
+	certPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("signer %s: unable to read %s: %w", signerName, caFile, err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("signer %s: unable to read %s: %w", signerName, keyFile, err)
+	}
+	caCert, caKey, err := decodeKeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("signer %s: %w", signerName, err)
+	}
+
+	return &Signer{
+		signerName:   signerName,
+		duration:     duration,
+		mode:         DiskBacked,
+		caCert:       caCert,
+		caKey:        caKey,
+		rootCertPath: caFile,
+	}, nil
+}
+
+func newEphemeralSigner(signerName string, duration time.Duration, alg KeyAlgorithm) (*Signer, error) {
+	if _, restarted := usedEphemeralSigners.LoadOrStore(signerName, true); restarted {
+		return nil, fmt.Errorf("signer %s: refusing to restart an ephemeral signer with a name already used in this process; "+
+			"certificates from the earlier root would no longer validate against the new one", signerName)
+	}
+
+	key, err := generateKey(alg)
+	if err != nil {
+		return nil, fmt.Errorf("signer %s: unable to generate ephemeral root key: %w", signerName, err)
+	}
+	caCert, der, err := selfSignRoot(signerName, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var certPEM bytes.Buffer
+	if err := pem.Encode(&certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		return nil, fmt.Errorf("signer %s: unable to encode ephemeral root: %w", signerName, err)
+	}
+
+	return &Signer{
+		signerName:  signerName,
+		duration:    duration,
+		mode:        Ephemeral,
+		caCert:      caCert,
+		caKey:       key,
+		rootCertPEM: certPEM.Bytes(),
+	}, nil
+}
+
+func loadOrGenerateRoot(signerName, certPath, keyPath string, alg KeyAlgorithm) (*x509.Certificate, crypto.Signer, error) {
+	if certPEM, err := os.ReadFile(certPath); err == nil {
+		keyPEM, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("signer %s: root cert exists but key is missing: %w", signerName, err)
+		}
+		return decodeKeyPair(certPEM, keyPEM)
+	}
+
+	key, err := generateKey(alg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("signer %s: unable to generate root key: %w", signerName, err)
+	}
+
+	cert, der, err := selfSignRoot(signerName, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := writePEM(certPath, "CERTIFICATE", der); err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("signer %s: unable to marshal root key: %w", signerName, err)
+	}
+	if err := writePEM(keyPath, "PRIVATE KEY", keyDER); err != nil {
+		return nil, nil, err
+	}
+
+	return cert, key, nil
+}
+
+// selfSignRoot creates a 10 year self-signed CA certificate for signerName
+// using key, returning both the parsed certificate and its raw DER bytes.
+func selfSignRoot(signerName string, key crypto.Signer) (*x509.Certificate, []byte, error) {
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: signerName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("signer %s: unable to self-sign root: %w", signerName, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("signer %s: unable to parse generated root: %w", signerName, err)
+	}
+	return cert, der, nil
+}
+
+// minRSAKeyBits is the smallest RSA modulus this package will sign a CSR
+// for; anything weaker is refused rather than silently issued.
+const minRSAKeyBits = 2048
+
+func checkPublicKeyStrength(pub crypto.PublicKey) error {
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil
+	}
+	if bits := rsaKey.N.BitLen(); bits < minRSAKeyBits {
+		return fmt.Errorf("RSA key size %d is weaker than the minimum %d bits", bits, minRSAKeyBits)
+	}
+	return nil
+}
+
+func decodeKeyPair(certPEM, keyPEM []byte) (*x509.Certificate, crypto.Signer, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, fmt.Errorf("unable to decode root certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse root certificate: %w", err)
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("unable to decode root key PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse root key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("root key does not implement crypto.Signer")
+	}
+	return cert, signer, nil
+}
+
+func writePEM(path, blockType string, der []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+	defer f.Close()
+	return pem.Encode(f, &pem.Block{Type: blockType, Bytes: der})
+}
+
+// GetRootCerts returns the path to this signer's root certificate PEM file.
+// It is only meaningful for a DiskBacked signer; an Ephemeral signer has no
+// on-disk root and returns an empty string - use RootCertPEM instead.
+func (s *Signer) GetRootCerts() string {
+	return s.rootCertPath
+}
+
+// RootCertPEM returns this signer's root certificate, PEM-encoded, reading
+// it from disk for a DiskBacked signer or returning the in-memory copy for
+// an Ephemeral one.
+func (s *Signer) RootCertPEM() ([]byte, error) {
+	if s.mode == Ephemeral {
+		return s.rootCertPEM, nil
+	}
+	pemBytes, err := os.ReadFile(s.rootCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("signer %s: unable to read root cert: %w", s.signerName, err)
+	}
+	return pemBytes, nil
+}
+
+// Sign issues a certificate for the given CSR bytes and requested usages,
+// returning the PEM-encoded leaf certificate. ttl shortens the certificate's
+// lifetime below this Signer's own duration; a ttl of zero, or one that
+// isn't shorter than the signer's duration, leaves the full duration in
+// effect. Callers are expected to have already rejected a ttl that exceeds
+// any policy maximum - Sign only ever shortens, never lengthens, what it
+// was constructed with.
+func (s *Signer) Sign(csrPEM []byte, usages []capi.KeyUsage, ttl time.Duration) ([]byte, error) {
+	duration := s.duration
+	if ttl > 0 && ttl < duration {
+		duration = ttl
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	if block == nil {
+		return nil, fmt.Errorf("signer %s: unable to decode CSR PEM", s.signerName)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("signer %s: unable to parse CSR: %w", s.signerName, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("signer %s: CSR signature invalid: %w", s.signerName, err)
+	}
+	if err := checkPublicKeyStrength(csr.PublicKey); err != nil {
+		return nil, fmt.Errorf("signer %s: %w", s.signerName, err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      csr.Subject,
+		DNSNames:     csr.DNSNames,
+		IPAddresses:  csr.IPAddresses,
+		NotBefore:    time.Now().Add(-5 * time.Minute),
+		NotAfter:     time.Now().Add(duration),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	for _, u := range usages {
+		if u == capi.UsageClientAuth {
+			tmpl.ExtKeyUsage = append(tmpl.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+		}
+		if u == capi.UsageServerAuth {
+			tmpl.ExtKeyUsage = append(tmpl.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, s.caCert, csr.PublicKey, s.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("signer %s: unable to sign certificate: %w", s.signerName, err)
+	}
+
+	var out bytes.Buffer
+	if err := pem.Encode(&out, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		return nil, fmt.Errorf("signer %s: unable to encode signed certificate: %w", s.signerName, err)
+	}
+	return out.Bytes(), nil
+}
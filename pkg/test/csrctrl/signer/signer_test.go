@@ -0,0 +1,185 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	capi "k8s.io/api/certificates/v1"
+)
+
+func TestSignRoundTripsEveryKeyAlgorithm(t *testing.T) {
+	for _, alg := range []KeyAlgorithm{RSA2048, RSA3072, ECDSAP256, ECDSAP384, Ed25519} {
+		alg := alg
+		t.Run(string(alg), func(t *testing.T) {
+			s, err := NewSigner(t.TempDir(), "test-signer", time.Hour, WithKeyAlgorithm(alg))
+			if err != nil {
+				t.Fatalf("NewSigner(%s): %v", alg, err)
+			}
+
+			csrPEM, err := newCSR(t, "leaf.example.com")
+			if err != nil {
+				t.Fatalf("newCSR: %v", err)
+			}
+
+			certPEM, err := s.Sign(csrPEM, []capi.KeyUsage{capi.UsageClientAuth}, 0)
+			if err != nil {
+				t.Fatalf("Sign(%s): %v", alg, err)
+			}
+
+			rootPEM, err := s.RootCertPEM()
+			if err != nil {
+				t.Fatalf("RootCertPEM: %v", err)
+			}
+
+			verifyChain(t, rootPEM, certPEM)
+		})
+	}
+}
+
+func TestNewSignerWithCAKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	seed, err := NewSigner(dir, "seed-signer", time.Hour)
+	if err != nil {
+		t.Fatalf("NewSigner(seed): %v", err)
+	}
+	seedRootPEM, err := seed.RootCertPEM()
+	if err != nil {
+		t.Fatalf("RootCertPEM(seed): %v", err)
+	}
+
+	caFile := seed.GetRootCerts()
+	keyFile := caFile[:len(caFile)-len(rootCertFileName)] + rootKeyFileName
+
+	s, err := NewSigner(t.TempDir(), "file-backed-signer", time.Hour, WithCAKeyFiles(caFile, keyFile))
+	if err != nil {
+		t.Fatalf("NewSigner(WithCAKeyFiles): %v", err)
+	}
+	rootPEM, err := s.RootCertPEM()
+	if err != nil {
+		t.Fatalf("RootCertPEM: %v", err)
+	}
+	if string(rootPEM) != string(seedRootPEM) {
+		t.Fatalf("signer loaded from CAFile/KeyFile reports a different root than the files it was pointed at")
+	}
+
+	csrPEM, err := newCSR(t, "leaf.example.com")
+	if err != nil {
+		t.Fatalf("newCSR: %v", err)
+	}
+	certPEM, err := s.Sign(csrPEM, []capi.KeyUsage{capi.UsageClientAuth}, 0)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	verifyChain(t, rootPEM, certPEM)
+}
+
+func TestEphemeralSignerRejectsRestart(t *testing.T) {
+	if _, err := NewSigner(t.TempDir(), "ephemeral-restart-signer", time.Hour, WithMode(Ephemeral)); err != nil {
+		t.Fatalf("NewSigner(Ephemeral): %v", err)
+	}
+	if _, err := NewSigner(t.TempDir(), "ephemeral-restart-signer", time.Hour, WithMode(Ephemeral)); err == nil {
+		t.Fatalf("NewSigner(Ephemeral): expected restarting the same signer name to be rejected, got nil error")
+	}
+}
+
+func TestEphemeralSignerCertsDoNotValidateAcrossRoots(t *testing.T) {
+	a, err := NewSigner(t.TempDir(), "ephemeral-signer-a", time.Hour, WithMode(Ephemeral))
+	if err != nil {
+		t.Fatalf("NewSigner(a): %v", err)
+	}
+	b, err := NewSigner(t.TempDir(), "ephemeral-signer-b", time.Hour, WithMode(Ephemeral))
+	if err != nil {
+		t.Fatalf("NewSigner(b): %v", err)
+	}
+
+	csrPEM, err := newCSR(t, "leaf.example.com")
+	if err != nil {
+		t.Fatalf("newCSR: %v", err)
+	}
+	leafPEM, err := a.Sign(csrPEM, []capi.KeyUsage{capi.UsageClientAuth}, 0)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	rootAPEM, err := a.RootCertPEM()
+	if err != nil {
+		t.Fatalf("RootCertPEM(a): %v", err)
+	}
+	rootBPEM, err := b.RootCertPEM()
+	if err != nil {
+		t.Fatalf("RootCertPEM(b): %v", err)
+	}
+	if string(rootAPEM) == string(rootBPEM) {
+		t.Fatalf("two ephemeral signers produced identical roots")
+	}
+
+	// The leaf must chain to its own signer's root...
+	verifyChain(t, rootAPEM, leafPEM)
+
+	// ...but must never validate against an unrelated signer's root, the way
+	// a certificate from a previous run of the same signer name never would.
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(rootBPEM) {
+		t.Fatalf("unable to parse root cert PEM")
+	}
+	block, _ := pem.Decode(leafPEM)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("unable to parse leaf cert: %v", err)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err == nil {
+		t.Fatalf("leaf signed by signer a unexpectedly validated against signer b's root")
+	}
+}
+
+func newCSR(t *testing.T, commonName string) ([]byte, error) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.CertificateRequest{Subject: pkix.Name{CommonName: commonName}}
+	der, err := x509.CreateCertificateRequest(rand.Reader, tmpl, key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+func verifyChain(t *testing.T, rootPEM, leafPEM []byte) {
+	t.Helper()
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(rootPEM) {
+		t.Fatalf("unable to parse root cert PEM")
+	}
+	block, _ := pem.Decode(leafPEM)
+	if block == nil {
+		t.Fatalf("unable to decode leaf cert PEM")
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("unable to parse leaf cert: %v", err)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		t.Fatalf("leaf cert does not chain to root: %v", err)
+	}
+}
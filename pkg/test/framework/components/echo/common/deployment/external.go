@@ -15,48 +15,59 @@
 package deployment
 
 import (
-	"path"
+	"fmt"
 	"strconv"
+	"time"
 
-	"github.com/apache/dubbo-go-pixiu/pkg/test/echo/common"
-	"github.com/apache/dubbo-go-pixiu/pkg/test/env"
 	"github.com/apache/dubbo-go-pixiu/pkg/test/framework/components/echo"
 	"github.com/apache/dubbo-go-pixiu/pkg/test/framework/components/echo/common/ports"
 	"github.com/apache/dubbo-go-pixiu/pkg/test/framework/components/echo/deployment"
 	"github.com/apache/dubbo-go-pixiu/pkg/test/framework/components/echo/match"
 	"github.com/apache/dubbo-go-pixiu/pkg/test/framework/components/namespace"
-	"github.com/apache/dubbo-go-pixiu/pkg/test/util/file"
 )
 
 const (
 	ExternalSvc      = "external"
 	ExternalHostname = "fake.external.com"
+
+	// externalCertTTL is used for certs minted through an XDSProvisioned
+	// TLSSource; short enough that rotation tests see it expire quickly.
+	externalCertTTL = 10 * time.Minute
 )
 
 type External struct {
 	// Namespace where external echo app will be deployed
 	Namespace namespace.Instance
 
+	// Signer, when set, makes External default to provisioning its server
+	// cert from a real csrctrl signer (XDSProvisioned) instead of the
+	// baked-in StaticPEM, whenever a CSR controller is available. See
+	// XDSProvisioned's doc comment for what this does and doesn't cover.
+	// The certificate is requested under the signer name ExternalSvc, so a
+	// *signer.Signer built with any other name rejects it and build panics.
+	Signer CSRSigner
+
+	// TLSSource overrides how the server cert is obtained. When nil it is
+	// chosen automatically based on whether Signer is set.
+	TLSSource TLSSource
+
 	// All external echo instances with no sidecar injected
 	All echo.Instances
 }
 
 func (e External) build(b deployment.Builder) deployment.Builder {
+	tlsSettings, err := e.tlsSource().Resolve()
+	if err != nil {
+		panic(fmt.Sprintf("deployment: unable to resolve TLS settings for %s: %v", ExternalSvc, err))
+	}
+
 	return b.WithConfig(echo.Config{
 		Service:           ExternalSvc,
 		Namespace:         e.Namespace,
 		DefaultHostHeader: ExternalHostname,
 		Ports:             ports.All(),
 		// Set up TLS certs on the server. This will make the server listen with these credentials.
-		TLSSettings: &common.TLSSettings{
-			// Echo has these test certs baked into the docker image
-			RootCert:   file.MustAsString(path.Join(env.IstioSrc, "tests/testdata/certs/dns/root-cert.pem")),
-			ClientCert: file.MustAsString(path.Join(env.IstioSrc, "tests/testdata/certs/dns/cert-chain.pem")),
-			Key:        file.MustAsString(path.Join(env.IstioSrc, "tests/testdata/certs/dns/key.pem")),
-			// Override hostname to match the SAN in the cert we are using
-			// TODO(nmittler): We should probably make this the same as ExternalHostname
-			Hostname: "server.default.svc",
-		},
+		TLSSettings: tlsSettings,
 		Subsets: []echo.SubsetConfig{
 			{
 				Version: "v1",
@@ -70,6 +81,21 @@ func (e External) build(b deployment.Builder) deployment.Builder {
 	})
 }
 
+func (e External) tlsSource() TLSSource {
+	if e.TLSSource != nil {
+		return e.TLSSource
+	}
+	if e.Signer != nil {
+		return XDSProvisioned{
+			Signer:     e.Signer,
+			SignerName: ExternalSvc,
+			SAN:        ExternalHostname,
+			TTL:        externalCertTTL,
+		}
+	}
+	return defaultStaticPEM()
+}
+
 func (e *External) loadValues(echos echo.Instances) error {
 	e.All = match.ServiceName(echo.NamespacedName{Name: ExternalSvc, Namespace: e.Namespace}).GetMatches(echos)
 	return nil
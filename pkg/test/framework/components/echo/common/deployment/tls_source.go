@@ -0,0 +1,106 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/apache/dubbo-go-pixiu/pkg/test/echo/common"
+	"github.com/apache/dubbo-go-pixiu/pkg/test/env"
+	"github.com/apache/dubbo-go-pixiu/pkg/test/util/file"
+)
+
+// TLSSource produces the TLSSettings an echo.Config server should listen
+// with. It exists so deployments can swap baked-in test PEMs for a
+// certificate issued by a csrctrl signer without changing anything else
+// about how the deployment is built.
+type TLSSource interface {
+	Resolve() (*common.TLSSettings, error)
+}
+
+// StaticPEM is the long-standing behavior: certs baked into the docker
+// image, loaded from disk once at build time.
+type StaticPEM struct {
+	RootCertFile   string
+	ClientCertFile string
+	KeyFile        string
+	// Hostname overrides the SNI/SAN the server is expected to answer for.
+	Hostname string
+}
+
+// defaultStaticPEM is the cert bundle External has always used.
+func defaultStaticPEM() StaticPEM {
+	dir := path.Join(env.IstioSrc, "tests/testdata/certs/dns")
+	return StaticPEM{
+		RootCertFile:   path.Join(dir, "root-cert.pem"),
+		ClientCertFile: path.Join(dir, "cert-chain.pem"),
+		KeyFile:        path.Join(dir, "key.pem"),
+		// Override hostname to match the SAN in the cert we are using.
+		Hostname: "server.default.svc",
+	}
+}
+
+func (s StaticPEM) Resolve() (*common.TLSSettings, error) {
+	return &common.TLSSettings{
+		RootCert:   file.MustAsString(s.RootCertFile),
+		ClientCert: file.MustAsString(s.ClientCertFile),
+		Key:        file.MustAsString(s.KeyFile),
+		Hostname:   s.Hostname,
+	}, nil
+}
+
+// CSRSigner is the subset of the csrctrl test signer that XDSProvisioned
+// needs: minting a short-lived certificate for a SAN. It is expressed as an
+// interface so this package doesn't need to depend on csrctrl directly;
+// *signer.Signer satisfies it.
+type CSRSigner interface {
+	// SignSAN returns a PEM leaf certificate and key bound to san, signed
+	// for signerName with the given TTL, plus the signer's PEM root.
+	SignSAN(signerName, san string, ttl time.Duration) (certPEM, keyPEM, rootPEM []byte, err error)
+}
+
+// XDSProvisioned gets a real, signer-issued certificate for SAN from a
+// csrctrl CSRSigner instead of a baked-in PEM, so the certificate a test
+// exercises actually came from the CSR-signing codepath rather than a file
+// checked into testdata. Resolve does a single issuance at deployment-build
+// time; there is no SDS socket or re-provisioning when TTL elapses, so this
+// does not by itself exercise rotation while a server is running - it only
+// swaps where the one-shot cert comes from. Rotation/revocation behavior of
+// the signer itself is covered by the csrctrl conformance suite, which talks
+// to the signer directly rather than through this TLSSource.
+type XDSProvisioned struct {
+	Signer     CSRSigner
+	SignerName string
+	SAN        string
+	TTL        time.Duration
+}
+
+func (x XDSProvisioned) Resolve() (*common.TLSSettings, error) {
+	if x.Signer == nil {
+		return nil, fmt.Errorf("deployment: XDSProvisioned requires a CSRSigner")
+	}
+	certPEM, keyPEM, rootPEM, err := x.Signer.SignSAN(x.SignerName, x.SAN, x.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("deployment: unable to provision cert for %s via signer %s: %w", x.SAN, x.SignerName, err)
+	}
+	return &common.TLSSettings{
+		RootCert:   string(rootPEM),
+		ClientCert: string(certPEM),
+		Key:        string(keyPEM),
+		Hostname:   x.SAN,
+	}, nil
+}
@@ -15,6 +15,7 @@
 package namespace
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/apache/dubbo-go-pixiu/pkg/test"
@@ -22,6 +23,34 @@ import (
 	"github.com/apache/dubbo-go-pixiu/pkg/test/scopes"
 )
 
+// IPFamily names the IP family a test expects a namespace's pods to use.
+// Only DualStack changes the namespace, by adding the dual-stack annotation;
+// IPv4, IPv6 and the zero value add nothing.
+type IPFamily string
+
+const (
+	IPv4      IPFamily = "IPv4"
+	IPv6      IPFamily = "IPv6"
+	DualStack IPFamily = "DualStack"
+)
+
+// DataPlaneMode selects how workloads in a namespace get their traffic
+// captured. The zero value behaves like Sidecar, leaving capture up to
+// Inject/Revision as it always has.
+type DataPlaneMode string
+
+const (
+	Sidecar     DataPlaneMode = "Sidecar"
+	Ambient     DataPlaneMode = "Ambient"
+	NoDataPlane DataPlaneMode = "None"
+)
+
+const (
+	dataPlaneModeLabel      = "istio.io/dataplane-mode"
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	dualStackAnnotation     = "istio.io/dual-stack"
+)
+
 // Config contains configuration information about the namespace instance
 type Config struct {
 	// Prefix to use for autogenerated namespace name
@@ -32,9 +61,75 @@ type Config struct {
 	Revision string
 	// Labels to be applied to namespace
 	Labels map[string]string
+	// Annotations to be applied to namespace
+	Annotations map[string]string
 	// SkipDump, if enabled, will disable dumping the namespace. This is useful to avoid duplicate
 	// dumping of istio-system.
 	SkipDump bool
+	// IPFamily selects the IP family of this namespace; only DualStack adds
+	// anything to the namespace (see IPFamily).
+	IPFamily IPFamily
+	// DataPlaneMode selects whether workloads in this namespace are captured
+	// by a sidecar, by ambient's ztunnel, or not at all. Leave empty for the
+	// existing sidecar-or-nothing behavior controlled by Inject.
+	DataPlaneMode DataPlaneMode
+}
+
+// validate rejects combinations that cannot be satisfied at once, such as
+// asking for both sidecar injection and ambient capture of the same
+// namespace.
+func (c *Config) validate() error {
+	if c.DataPlaneMode == Ambient && c.Inject {
+		return fmt.Errorf("namespace %s: Inject cannot be combined with DataPlaneMode=Ambient; "+
+			"ambient namespaces are captured by ztunnel, not the sidecar injector", c.Prefix)
+	}
+	if c.DataPlaneMode == NoDataPlane && c.Inject {
+		return fmt.Errorf("namespace %s: Inject cannot be combined with DataPlaneMode=None", c.Prefix)
+	}
+	return nil
+}
+
+// effectiveLabels returns Labels merged with the labels implied by
+// DataPlaneMode, so newKube and claimKube don't need callers to open-code
+// istio.io/dataplane-mode or pod-security.kubernetes.io/* by hand.
+//
+// newKube/claimKube are expected to build their ObjectMeta.Labels/Annotations
+// straight from cfg.Labels/cfg.Annotations once this has run - they must not
+// read c.Labels/c.Annotations before applyEffectiveMetadata has replaced them,
+// the way this package's Claim/New already ensure.
+func (c *Config) effectiveLabels() map[string]string {
+	out := make(map[string]string, len(c.Labels)+1)
+	for k, v := range c.Labels {
+		out[k] = v
+	}
+	if c.DataPlaneMode == Ambient {
+		out[dataPlaneModeLabel] = "ambient"
+		out[podSecurityEnforceLabel] = "privileged"
+	}
+	return out
+}
+
+// effectiveAnnotations returns Annotations merged with the annotation
+// implied by IPFamily, so newKube and claimKube don't need callers to
+// open-code the dual-stack marker by hand.
+func (c *Config) effectiveAnnotations() map[string]string {
+	out := make(map[string]string, len(c.Annotations)+1)
+	for k, v := range c.Annotations {
+		out[k] = v
+	}
+	if c.IPFamily == DualStack {
+		out[dualStackAnnotation] = "true"
+	}
+	return out
+}
+
+// applyEffectiveMetadata replaces Labels/Annotations with the merged result
+// of effectiveLabels/effectiveAnnotations, so claimKube/newKube - which only
+// ever read cfg.Labels/cfg.Annotations - pick up what DataPlaneMode and
+// IPFamily imply without needing their own copy of this logic.
+func (c *Config) applyEffectiveMetadata() {
+	c.Labels = c.effectiveLabels()
+	c.Annotations = c.effectiveAnnotations()
 }
 
 func (c *Config) overwriteRevisionIfEmpty(revision string) {
@@ -61,7 +156,11 @@ type Instance interface {
 
 // Claim an existing namespace in all clusters, or create a new one if doesn't exist.
 func Claim(ctx resource.Context, cfg Config) (i Instance, err error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 	cfg.overwriteRevisionIfEmpty(ctx.Settings().Revisions.Default())
+	cfg.applyEffectiveMetadata()
 	return claimKube(ctx, cfg)
 }
 
@@ -92,10 +191,14 @@ func New(ctx resource.Context, cfg Config) (i Instance, err error) {
 		}
 	}()
 
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 	if ctx.Settings().StableNamespaces {
 		return Claim(ctx, cfg)
 	}
 	cfg.overwriteRevisionIfEmpty(ctx.Settings().Revisions.Default())
+	cfg.applyEffectiveMetadata()
 	return newKube(ctx, cfg)
 }
 
@@ -126,6 +229,21 @@ func Setup(ns *Instance, cfg Config) resource.SetupFn {
 	}
 }
 
+// SetupAmbient is a utility function for creating a namespace configured for
+// ambient mesh capture in a test suite, mirroring Setup.
+func SetupAmbient(ns *Instance, cfg Config) resource.SetupFn {
+	cfg.DataPlaneMode = Ambient
+	cfg.Inject = false
+	return Setup(ns, cfg)
+}
+
+// SetupDualStack is a utility function for creating a dual-stack namespace in
+// a test suite, mirroring Setup.
+func SetupDualStack(ns *Instance, cfg Config) resource.SetupFn {
+	cfg.IPFamily = DualStack
+	return Setup(ns, cfg)
+}
+
 // Getter for a namespace Instance
 type Getter func() Instance
 